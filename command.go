@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"os"
 	"slices"
+	"sort"
 	"strings"
 )
 
 // Handler represents a command function called by [Command.Execute].
-// The command flags can be accessed from the FlagSet parameter using [Lookup] or [flag.Lookup].
-type Handler func(context.Context, *flag.FlagSet, []string) error
+// Options declared with [Command.Options] can be read from ctx using [Get];
+// flags declared with [Command.Flags] remain reachable through
+// ctx.FlagSet() and [Lookup] or [flag.Lookup].
+type Handler func(parent context.Context, ctx *Context) error
 
 // Middleware represents a function used to wrap a [Handler]. They can be used to make actions that will execute before or after the command.
 // They are also inherited by subcommands, unlike command actions.
@@ -36,16 +39,96 @@ type Command interface {
 
 	// Flags is used to declare the flags of a command.
 	Flags(func(*flag.FlagSet)) Command
+
+	// Options declares a command's typed options, read back in a Handler
+	// through [Get]. It is built on the same flag set as Flags, so the two
+	// can be mixed freely on a single command.
+	Options(...OptionSpec) Command
+
+	// CompleteFlag registers a dynamic completion function for the named flag,
+	// used by the shell completion scripts generated by [Command.Completions].
+	CompleteFlag(name string, fn CompletionFunc) Command
+
+	// CompleteArgs registers a dynamic completion function for the command's
+	// positional arguments.
+	CompleteArgs(fn CompletionFunc) Command
+
+	// Completions generates a completion script for the given shell.
+	Completions(shell Shell) (string, error)
+
+	// Config loads path and applies its values to flags declared anywhere in
+	// the command tree, with lowest precedence: values explicitly passed on
+	// the command line or through [Command.FromEnv] always win. Keys map
+	// hierarchically to the subcommand path, e.g. repos.list.user applies to
+	// the user flag of the "repos list" subcommand. The format is guessed
+	// from the file extension unless one is passed explicitly.
+	Config(path string, formats ...ConfigFormat) Command
+
+	// FromEnv enables overriding any flag in the command tree from an
+	// environment variable named prefix + the flag's command path, uppercased
+	// and joined with underscores, e.g. FromEnv("APP") lets
+	// APP_REPOS_LIST_USER override the user flag of "repos list". Environment
+	// variables take precedence over the config file loaded by
+	// [Command.Config], but not over flags passed on the command line.
+	FromEnv(prefix string) Command
+
+	// Aliases registers additional names that dispatch to this subcommand
+	// from its parent, alongside the name it was created with.
+	Aliases(...string) Command
+
+	// Hidden omits the command from its parent's usage output. It remains
+	// executable.
+	Hidden() Command
+
+	// Category groups the command under the given heading in its parent's
+	// usage output. Commands without a category are listed under the
+	// existing flat "Subcommands:" heading.
+	Category(string) Command
+
+	// OnError registers a hook invoked before Execute exits the process,
+	// whether on its own errors (unknown command, missing required option)
+	// or on a handler/middleware's returned error. The default hook prints
+	// the error, if any, and calls os.Exit with its [ExitError] code (1 for
+	// any other error). Overriding it to avoid os.Exit makes Execute return
+	// the error instead, which is useful for table-driven tests.
+	OnError(func(context.Context, error)) Command
+
+	// Before adds a hook run once, after flags are parsed and required
+	// options are validated, but before the handler. Before hooks across the
+	// resolved command chain run root-first; any returned error skips the
+	// handler and is handled like one returned by it.
+	Before(Handler) Command
+
+	// After adds a hook run unconditionally once any Before hook has run,
+	// including if the handler or a Before hook panics or returns an error,
+	// via defer. After hooks across the resolved command chain run
+	// leaf-first (LIFO), the same order Go defers would if the chain's hooks
+	// had been registered as one. Use [HandlerError] to inspect the result
+	// that triggered cleanup.
+	After(Handler) Command
 }
 
 type command struct {
-	name        string
-	help        string
-	middlewares []Middleware
-	handler     Handler
-	subCommands map[string]*command
-	flagSet     *flag.FlagSet
-	parent      *command
+	name            string
+	help            string
+	hidden          bool
+	category        string
+	aliases         []string
+	middlewares     []Middleware
+	handler         Handler
+	subCommands     map[string]*command
+	aliasCommands   map[string]*command
+	flagSet         *flag.FlagSet
+	parent          *command
+	completeFlags   map[string]CompletionFunc
+	completeArgs    CompletionFunc
+	configTree      map[string]any
+	configErr       error
+	envPrefix       string
+	requiredOptions []string
+	onError         func(context.Context, error)
+	beforeHooks     []Handler
+	afterHooks      []Handler
 }
 
 // Root creates a new root command.
@@ -57,6 +140,7 @@ func Root() Command {
 	}
 
 	flag.CommandLine.Usage = command.usage
+	command.registerCompletionSupport()
 
 	return &command
 }
@@ -79,18 +163,95 @@ func (c *command) Middlewares(middlewares ...Middleware) Command {
 	return c
 }
 
+func (c *command) Aliases(aliases ...string) Command {
+	c.aliases = append(c.aliases, aliases...)
+
+	if c.parent != nil {
+		if c.parent.aliasCommands == nil {
+			c.parent.aliasCommands = map[string]*command{}
+		}
+		for _, alias := range aliases {
+			c.parent.aliasCommands[alias] = c
+		}
+	}
+
+	return c
+}
+
+func (c *command) Hidden() Command {
+	c.hidden = true
+	return c
+}
+
+func (c *command) Category(category string) Command {
+	c.category = category
+	return c
+}
+
 func (c *command) Action(handler Handler) Command {
 	c.handler = handler
 	return c
 }
 
+func (c *command) Before(hook Handler) Command {
+	c.beforeHooks = append(c.beforeHooks, hook)
+	return c
+}
+
+func (c *command) After(hook Handler) Command {
+	c.afterHooks = append(c.afterHooks, hook)
+	return c
+}
+
+// handlerErrKey is the context key [HandlerError] reads from, set by Execute
+// around After hooks.
+type handlerErrKey struct{}
+
+// HandlerError returns the error that triggered cleanup, for use inside an
+// [Command.After] hook: the handler's returned error, or a Before hook's if
+// the handler never ran. It is nil outside of an After hook, or on success.
+func HandlerError(ctx context.Context) error {
+	err, _ := ctx.Value(handlerErrKey{}).(error)
+	return err
+}
+
 func (c *command) Execute(ctx context.Context) error {
+	if c.configErr != nil {
+		return c.exit(ctx, c.configErr)
+	}
+
+	if c.configTree != nil {
+		if err := c.validateConfigTree(c.configTree, nil); err != nil {
+			return c.exit(ctx, err)
+		}
+	}
+
 	command, args := c, os.Args[1:]
 	middlewares := slices.Clone(c.middlewares)
+	requiredOptions := slices.Clone(c.requiredOptions)
+	beforeHooks := slices.Clone(c.beforeHooks)
+	afterHooks := slices.Clone(c.afterHooks)
+	configSubtree := c.configTree
 	for {
+		if configSubtree != nil {
+			if err := applyConfigValues(command, configSubtree); err != nil {
+				return c.exit(ctx, err)
+			}
+		}
+
+		if c.envPrefix != "" {
+			if err := applyEnvValues(c.envPrefix, command); err != nil {
+				return c.exit(ctx, err)
+			}
+		}
+
 		if err := command.flagSet.Parse(args); err != nil {
 			// This should never occur because the flag sets use flag.ExitOnError
-			os.Exit(2) // Use 2 to mimick the behavior of flag.ExitOnError
+			return c.exit(ctx, &ExitError{Code: 2, Err: err})
+		}
+
+		if command == c && c.handleGenerateCompletion() {
+			return c.exit(ctx, nil)
 		}
 
 		args = command.flagSet.Args()
@@ -99,6 +260,9 @@ func (c *command) Execute(ctx context.Context) error {
 		}
 
 		subCommand, ok := command.subCommands[args[0]]
+		if !ok {
+			subCommand, ok = command.aliasCommands[args[0]]
+		}
 		if !ok {
 			break
 		}
@@ -107,9 +271,17 @@ func (c *command) Execute(ctx context.Context) error {
 			subCommand.flagSet.Var(f.Value, f.Name, f.Usage)
 		})
 
+		if configSubtree != nil {
+			next, _ := configSubtree[subCommand.name].(map[string]any)
+			configSubtree = next
+		}
+
 		command = subCommand
 		args = args[1:]
 		middlewares = append(middlewares, subCommand.middlewares...)
+		requiredOptions = append(requiredOptions, subCommand.requiredOptions...)
+		beforeHooks = append(beforeHooks, subCommand.beforeHooks...)
+		afterHooks = append(afterHooks, subCommand.afterHooks...)
 	}
 
 	if command.handler == nil {
@@ -117,19 +289,49 @@ func (c *command) Execute(ctx context.Context) error {
 			command.flagSet.SetOutput(os.Stderr)
 			fmt.Fprintf(command.flagSet.Output(), "command provided but not defined: %s\n", args[0])
 			command.usage()
-			os.Exit(2) // Use 2 to mimick the behavior of flag.ExitOnError
+			return c.exit(ctx, &ExitError{Code: 2}) // Use 2 to mimick the behavior of flag.ExitOnError
 		}
 
 		command.usage()
-		os.Exit(0)
+		return c.exit(ctx, nil)
 	}
 
-	handler := command.handler
-	for i := len(middlewares) - 1; i >= 0; i-- {
-		handler = middlewares[i](handler)
+	if command.name != completeSubCommand {
+		if err := checkRequiredOptions(command.flagSet, requiredOptions); err != nil {
+			return c.exit(ctx, err)
+		}
 	}
 
-	return handler(ctx, command.flagSet, args)
+	cmdCtx := &Context{flagSet: command.flagSet, Args: args}
+
+	var handlerErr error
+	func() {
+		defer func() {
+			afterCtx := context.WithValue(ctx, handlerErrKey{}, handlerErr)
+			for i := len(afterHooks) - 1; i >= 0; i-- {
+				afterHooks[i](afterCtx, cmdCtx)
+			}
+		}()
+
+		for _, hook := range beforeHooks {
+			if handlerErr = hook(ctx, cmdCtx); handlerErr != nil {
+				return
+			}
+		}
+
+		handler := command.handler
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			handler = middlewares[i](handler)
+		}
+
+		handlerErr = handler(ctx, cmdCtx)
+	}()
+
+	if handlerErr != nil {
+		return c.exit(ctx, handlerErr)
+	}
+
+	return nil
 }
 
 func (c *command) Help(help string) Command {
@@ -153,17 +355,30 @@ func (c *command) usage() {
 	}
 
 	var nbFlags int
-	c.flagSet.VisitAll(func(*flag.Flag) {
+	displayFlags := flag.NewFlagSet(c.flagSet.Name(), flag.ContinueOnError)
+	displayFlags.SetOutput(&builder)
+	c.flagSet.VisitAll(func(f *flag.Flag) {
+		if f.Name == generateCompletionFlag {
+			return
+		}
 		nbFlags++
+		displayFlags.Var(f.Value, f.Name, f.Usage)
 	})
 
+	var nbSubCommands int
+	for _, subCommand := range c.subCommands {
+		if !subCommand.hidden {
+			nbSubCommands++
+		}
+	}
+
 	optionsHint := ""
 	if nbFlags > 0 {
 		optionsHint = " [OPTIONS]"
 	}
 
 	subCommandHint := ""
-	if len(c.subCommands) > 0 {
+	if nbSubCommands > 0 {
 		subCommandHint = " [COMMAND]"
 		if c.handler == nil {
 			subCommandHint = " COMMAND"
@@ -185,22 +400,65 @@ func (c *command) usage() {
 	if nbFlags > 0 {
 		builder.WriteString("\n")
 		builder.WriteString("Options:\n")
-		c.flagSet.PrintDefaults()
+		displayFlags.PrintDefaults()
 	}
 
-	if len(c.subCommands) > 0 {
-		builder.WriteString("\n")
-		builder.WriteString("Subcommands:")
-
-		for name, subCommand := range c.subCommands {
-			builder.WriteString("\n  ")
-			builder.WriteString(name)
-			if subCommand.help != "" {
-				builder.WriteString("\n\t")
-				builder.WriteString(subCommand.help)
-			}
+	if nbSubCommands > 0 {
+		writeSubCommands(&builder, c.subCommands, "Subcommands:", c.visibleSubCommands(""))
+
+		for _, category := range c.subCommandCategories() {
+			writeSubCommands(&builder, c.subCommands, category+":", c.visibleSubCommands(category))
 		}
 	}
 
 	fmt.Fprintln(output, builder.String())
 }
+
+// visibleSubCommands returns the names of c's non-hidden subcommands in the
+// given category, sorted alphabetically. The empty category selects
+// uncategorized subcommands.
+func (c *command) visibleSubCommands(category string) []string {
+	var names []string
+	for name, subCommand := range c.subCommands {
+		if subCommand.hidden || subCommand.category != category {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// subCommandCategories returns the distinct, non-empty categories used by
+// c's subcommands, sorted alphabetically.
+func (c *command) subCommandCategories() []string {
+	seen := map[string]bool{}
+	var categories []string
+	for _, subCommand := range c.subCommands {
+		if subCommand.hidden || subCommand.category == "" || seen[subCommand.category] {
+			continue
+		}
+		seen[subCommand.category] = true
+		categories = append(categories, subCommand.category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func writeSubCommands(builder *strings.Builder, subCommands map[string]*command, header string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	builder.WriteString("\n")
+	builder.WriteString(header)
+
+	for _, name := range names {
+		builder.WriteString("\n  ")
+		builder.WriteString(name)
+		if help := subCommands[name].help; help != "" {
+			builder.WriteString("\n\t")
+			builder.WriteString(help)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// resetCommandLine gives each test its own flag.CommandLine, since [Root]
+// registers onto the package-global flag set and subsequent Root calls
+// would otherwise panic on "flag redefined".
+func resetCommandLine() {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+}
+
+func TestExecuteRequiredOptionMissing(t *testing.T) {
+	resetCommandLine()
+
+	root := Root().Options(Option[string]{Name: "mode", Required: true})
+
+	var ranHandler bool
+	root.Action(func(ctx context.Context, cmdCtx *Context) error {
+		ranHandler = true
+		return nil
+	})
+
+	var onErrorErr error
+	root.OnError(func(ctx context.Context, err error) {
+		onErrorErr = err
+	})
+
+	os.Args = []string{"bin"}
+	err := root.Execute(context.Background())
+	if err == nil {
+		t.Fatal("Execute() returned nil error, want missing required option error")
+	}
+	if ranHandler {
+		t.Error("handler ran despite the required option being missing")
+	}
+	if !strings.Contains(err.Error(), "mode") {
+		t.Errorf("Execute() error = %q, want it to name the missing option", err)
+	}
+	if onErrorErr != err {
+		t.Errorf("OnError received %v, want the same error returned by Execute", onErrorErr)
+	}
+}
+
+func TestExecuteConfigEnvCLIPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"level":"config"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		loadConfig bool
+		env        string
+		args       []string
+		want       string
+	}{
+		{name: "default applies when nothing else is set", want: "default"},
+		{name: "config overrides default", loadConfig: true, want: "config"},
+		{name: "env overrides config", loadConfig: true, env: "env", want: "env"},
+		{name: "cli overrides env and config", loadConfig: true, env: "env", args: []string{"--level=cli"}, want: "cli"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resetCommandLine()
+
+			root := Root().FromEnv("APP")
+			if tc.loadConfig {
+				root = root.Config(path)
+			}
+			root.Options(Option[string]{Name: "level", Default: "default"})
+
+			if tc.env != "" {
+				t.Setenv("APP_LEVEL", tc.env)
+			}
+
+			var got string
+			root.Action(func(ctx context.Context, cmdCtx *Context) error {
+				got = Get[string](cmdCtx, "level")
+				return nil
+			})
+			root.OnError(func(ctx context.Context, err error) {
+				t.Fatalf("unexpected error: %v", err)
+			})
+
+			os.Args = append([]string{"bin"}, tc.args...)
+			if err := root.Execute(context.Background()); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("level = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteBeforeAfterLIFOWithPanic(t *testing.T) {
+	resetCommandLine()
+
+	var order []string
+
+	root := Root()
+	root.Before(func(ctx context.Context, cmdCtx *Context) error {
+		order = append(order, "root-before")
+		return nil
+	})
+	root.After(func(ctx context.Context, cmdCtx *Context) error {
+		order = append(order, "root-after")
+		return nil
+	})
+
+	sub := root.SubCommand("sub")
+	sub.Before(func(ctx context.Context, cmdCtx *Context) error {
+		order = append(order, "sub-before")
+		return nil
+	})
+	sub.After(func(ctx context.Context, cmdCtx *Context) error {
+		order = append(order, "sub-after")
+		return nil
+	})
+	sub.Action(func(ctx context.Context, cmdCtx *Context) error {
+		panic("boom")
+	})
+
+	root.OnError(func(ctx context.Context, err error) {})
+
+	os.Args = []string{"bin", "sub"}
+	func() {
+		defer func() { recover() }()
+		root.Execute(context.Background())
+	}()
+
+	want := []string{"root-before", "sub-before", "sub-after", "root-after"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("hook order = %v, want %v", order, want)
+	}
+}
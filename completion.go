@@ -0,0 +1,217 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// completeSubCommand is the name of the hidden subcommand used by generated
+// completion scripts to ask the binary for candidates.
+const completeSubCommand = "__complete"
+
+// generateCompletionFlag is the hidden root flag used to print a completion
+// script for a given shell instead of running the command.
+const generateCompletionFlag = "generate-completion"
+
+// Shell identifies a shell supported by [Command.Completions].
+type Shell string
+
+const (
+	ShellBash Shell = "bash"
+	ShellZsh  Shell = "zsh"
+	ShellFish Shell = "fish"
+)
+
+// CompletionFunc returns dynamic completion candidates for the given prefix.
+// It is invoked by the hidden __complete subcommand.
+type CompletionFunc func(ctx context.Context, prefix string) []string
+
+// registerCompletionSupport wires the hidden --generate-completion flag and
+// __complete subcommand onto the root command. It must only be called once,
+// from [Root].
+func (c *command) registerCompletionSupport() {
+	c.flagSet.String(generateCompletionFlag, "", "Generate a shell completion script (bash, zsh, fish) and exit")
+
+	complete := &command{
+		name:        completeSubCommand,
+		subCommands: map[string]*command{},
+		flagSet:     flag.NewFlagSet(completeSubCommand, flag.ExitOnError),
+		parent:      c,
+		hidden:      true,
+	}
+	complete.handler = completeHandler(c)
+	c.subCommands[completeSubCommand] = complete
+}
+
+// handleGenerateCompletion prints the requested shell's completion script to
+// stdout when --generate-completion was passed on the root command, or the
+// error if the shell is unsupported. It reports whether the flag was passed,
+// in which case Execute should return immediately.
+func (c *command) handleGenerateCompletion() bool {
+	shell := Lookup[string](c.flagSet, generateCompletionFlag)
+	if shell == "" {
+		return false
+	}
+
+	script, err := c.Completions(Shell(shell))
+	if err != nil {
+		fmt.Fprintln(c.flagSet.Output(), err)
+		return true
+	}
+
+	fmt.Println(script)
+	return true
+}
+
+// CompleteFlag registers a dynamic completion function for the named flag.
+func (c *command) CompleteFlag(name string, fn CompletionFunc) Command {
+	if c.completeFlags == nil {
+		c.completeFlags = map[string]CompletionFunc{}
+	}
+	c.completeFlags[name] = fn
+	return c
+}
+
+// CompleteArgs registers a dynamic completion function for the command's
+// positional arguments.
+func (c *command) CompleteArgs(fn CompletionFunc) Command {
+	c.completeArgs = fn
+	return c
+}
+
+// Completions generates a completion script for the given shell. The script
+// invokes this binary with the hidden __complete subcommand to ask for
+// candidates at completion time.
+func (c *command) Completions(shell Shell) (string, error) {
+	bin := filepath.Base(c.name)
+
+	switch shell {
+	case ShellBash:
+		return fmt.Sprintf(bashCompletionTemplate, bin, bin, bin, bin), nil
+
+	case ShellZsh:
+		return fmt.Sprintf(zshCompletionTemplate, bin, bin, bin, bin, bin), nil
+
+	case ShellFish:
+		return fmt.Sprintf(fishCompletionTemplate, bin, bin, bin, bin), nil
+
+	default:
+		return "", fmt.Errorf("unsupported shell: %s", shell)
+	}
+}
+
+// completeHandler walks root's command tree following the subcommand path in
+// args, then prints completion candidates for the final word on stdout, one
+// per line.
+func completeHandler(root *command) Handler {
+	return func(ctx context.Context, cmdCtx *Context) error {
+		current := root
+		words := cmdCtx.Args
+		for len(words) > 1 {
+			next, ok := current.subCommands[words[0]]
+			if !ok || next.hidden {
+				break
+			}
+			current = next
+			words = words[1:]
+		}
+
+		prefix := ""
+		if len(words) > 0 {
+			prefix = words[len(words)-1]
+		}
+
+		var candidates []string
+		switch {
+		case strings.HasPrefix(prefix, "-"):
+			candidates = completeFlagNames(current, prefix)
+
+		default:
+			if fn, ok := completingFlagValue(current, words); ok {
+				candidates = fn(ctx, prefix)
+			} else {
+				candidates = completeSubCommands(current, prefix)
+				if current.completeArgs != nil {
+					candidates = append(candidates, current.completeArgs(ctx, prefix)...)
+				}
+			}
+		}
+
+		sort.Strings(candidates)
+		for _, candidate := range candidates {
+			fmt.Println(candidate)
+		}
+
+		return nil
+	}
+}
+
+// completingFlagValue reports whether words ends with a flag name that has a
+// registered [Command.CompleteFlag] function on c, e.g. ["--user", "oct"]
+// while "oct" is still being typed, and returns that function if so.
+func completingFlagValue(c *command, words []string) (CompletionFunc, bool) {
+	if len(words) < 2 {
+		return nil, false
+	}
+
+	name := strings.TrimLeft(words[len(words)-2], "-")
+	fn, ok := c.completeFlags[name]
+	return fn, ok
+}
+
+func completeFlagNames(c *command, prefix string) []string {
+	var candidates []string
+
+	c.flagSet.VisitAll(func(f *flag.Flag) {
+		flagName := "--" + f.Name
+		if strings.HasPrefix(flagName, prefix) {
+			candidates = append(candidates, flagName)
+		}
+	})
+
+	return candidates
+}
+
+func completeSubCommands(c *command, prefix string) []string {
+	var candidates []string
+
+	for name, sub := range c.subCommands {
+		if sub.hidden {
+			continue
+		}
+		if strings.HasPrefix(name, prefix) {
+			candidates = append(candidates, name)
+		}
+	}
+
+	return candidates
+}
+
+const bashCompletionTemplate = `_%s_completions() {
+    local words
+    words=("${COMP_WORDS[@]:1}")
+    COMPREPLY=($(compgen -W "$(%s __complete -- "${words[@]}")" -- "${COMP_WORDS[COMP_CWORD]}"))
+}
+complete -F _%s_completions %s
+`
+
+const zshCompletionTemplate = `#compdef %s
+autoload -U +X bashcompinit && bashcompinit
+_%s_completions() {
+    local words
+    words=("${COMP_WORDS[@]:1}")
+    COMPREPLY=($(compgen -W "$(%s __complete -- "${words[@]}")" -- "${COMP_WORDS[COMP_CWORD]}"))
+}
+complete -F _%s_completions %s
+`
+
+const fishCompletionTemplate = `function __%s_complete
+    set -l words (commandline -opc) (commandline -ct)
+    %s __complete -- $words[2..-1]
+end
+complete -c %s -f -a '(__%s_complete)'
+`
@@ -0,0 +1,192 @@
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFormat identifies the encoding of a file passed to [Command.Config].
+type ConfigFormat int
+
+const (
+	// ConfigFormatAuto detects the format from the file's extension.
+	ConfigFormatAuto ConfigFormat = iota
+	ConfigFormatJSON
+	ConfigFormatYAML
+	ConfigFormatTOML
+)
+
+// Config loads path and stores its values to be applied to the command
+// tree's flags once [Command.Execute] resolves which subcommand is running.
+// Reading or parsing errors are returned from Execute rather than here, to
+// match the rest of the package's lazily-evaluated builder methods.
+func (c *command) Config(path string, formats ...ConfigFormat) Command {
+	format := ConfigFormatAuto
+	if len(formats) > 0 {
+		format = formats[0]
+	}
+
+	tree, err := loadConfigFile(path, format)
+	if err != nil {
+		c.configErr = err
+		return c
+	}
+
+	c.configTree = tree
+	return c
+}
+
+// FromEnv records the environment variable prefix used to override flags
+// across the whole command tree. See the Command.FromEnv doc comment for the
+// naming scheme.
+func (c *command) FromEnv(prefix string) Command {
+	c.envPrefix = prefix
+	return c
+}
+
+func loadConfigFile(path string, format ConfigFormat) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("command: config: failed to read %s: %w", path, err)
+	}
+
+	if format == ConfigFormatAuto {
+		format = detectConfigFormat(path)
+	}
+
+	tree := map[string]any{}
+	switch format {
+	case ConfigFormatJSON:
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("command: config: failed to parse %s as JSON: %w", path, err)
+		}
+
+	case ConfigFormatYAML:
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("command: config: failed to parse %s as YAML: %w", path, err)
+		}
+
+	case ConfigFormatTOML:
+		if _, err := toml.Decode(string(data), &tree); err != nil {
+			return nil, fmt.Errorf("command: config: failed to parse %s as TOML: %w", path, err)
+		}
+
+	default:
+		return nil, fmt.Errorf("command: config: cannot detect format of %s, pass one explicitly", path)
+	}
+
+	return tree, nil
+}
+
+func detectConfigFormat(path string) ConfigFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ConfigFormatJSON
+	case ".yaml", ".yml":
+		return ConfigFormatYAML
+	case ".toml":
+		return ConfigFormatTOML
+	default:
+		return ConfigFormatAuto
+	}
+}
+
+// validateConfigTree walks tree and fails on any key that does not map to
+// either a flag or a subcommand of c, naming the offending dotted path.
+func (c *command) validateConfigTree(tree map[string]any, path []string) error {
+	for key, value := range tree {
+		fullPath := append(append([]string{}, path...), key)
+
+		if sub, ok := value.(map[string]any); ok {
+			subCommand, ok := c.subCommands[key]
+			if !ok {
+				return fmt.Errorf("command: config: unknown key %q", strings.Join(fullPath, "."))
+			}
+			if err := subCommand.validateConfigTree(sub, fullPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if c.flagSet.Lookup(key) == nil {
+			return fmt.Errorf("command: config: unknown key %q", strings.Join(fullPath, "."))
+		}
+	}
+
+	return nil
+}
+
+// applyConfigValues sets cmd's flags from tree's scalar values. tree has
+// already been validated by validateConfigTree, so any lookup miss here is a
+// namespace key belonging to a different subcommand and is skipped.
+func applyConfigValues(cmd *command, tree map[string]any) error {
+	for key, value := range tree {
+		if _, ok := value.(map[string]any); ok {
+			continue
+		}
+
+		f := cmd.flagSet.Lookup(key)
+		if f == nil {
+			continue
+		}
+
+		if err := f.Value.Set(formatConfigValue(value)); err != nil {
+			return fmt.Errorf("command: config: invalid value for %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// formatConfigValue renders a decoded config scalar as flag.Value.Set text.
+// JSON numbers decode to float64; fmt.Sprint would render large integers in
+// exponential notation (e.g. "1.23456789012345e+14"), which flag parsers
+// like strconv.ParseInt reject, so those are formatted without exponents
+// instead. YAML and TOML already decode integers to their own Go type, which
+// fmt.Sprint renders correctly.
+func formatConfigValue(value any) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// applyEnvValues overrides cmd's flags from environment variables named
+// prefix + the flag's command path, uppercased and underscore-joined.
+func applyEnvValues(prefix string, cmd *command) error {
+	var applyErr error
+
+	cmd.flagSet.VisitAll(func(f *flag.Flag) {
+		if applyErr != nil {
+			return
+		}
+
+		value, ok := os.LookupEnv(envVarName(prefix, cmd, f.Name))
+		if !ok {
+			return
+		}
+
+		if err := f.Value.Set(value); err != nil {
+			applyErr = fmt.Errorf("command: env: invalid value for %s: %w", f.Name, err)
+		}
+	})
+
+	return applyErr
+}
+
+func envVarName(prefix string, cmd *command, flagName string) string {
+	parts := []string{flagName}
+	for cur := cmd; cur != nil && cur.parent != nil; cur = cur.parent {
+		parts = append([]string{cur.name}, parts...)
+	}
+
+	return strings.ToUpper(prefix + "_" + strings.Join(parts, "_"))
+}
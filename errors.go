@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ExitError carries the process exit code alongside the underlying error.
+// Handlers and middlewares can return one, typically via [Exit], to choose
+// their own exit code; any other error defaults to code 1.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("command: exit code %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Exit returns an error that makes [Command.Execute] exit with code once it
+// reaches the root's [Command.OnError] hook.
+func Exit(code int, msg string) error {
+	return &ExitError{Code: code, Err: errors.New(msg)}
+}
+
+// asExitError normalizes err to an *ExitError, defaulting to code 1.
+func asExitError(err error) *ExitError {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr
+	}
+	return &ExitError{Code: 1, Err: err}
+}
+
+// OnError registers a hook invoked by [Command.Execute] before the process
+// exits, both for its own errors (unknown command, missing required option)
+// and for a handler or middleware's returned error. err is nil when Execute
+// is merely printing usage and exiting 0. Overriding the hook to avoid
+// calling os.Exit makes Execute return the error instead of terminating the
+// process, which table-driven tests of subcommand dispatch rely on.
+func (c *command) OnError(fn func(context.Context, error)) Command {
+	c.onError = fn
+	return c
+}
+
+// exit reports err to c's OnError hook, defaulting to printing it to stderr
+// and exiting the process with its code. It returns err so an overridden
+// hook that does not terminate makes Execute return normally.
+func (c *command) exit(ctx context.Context, err error) error {
+	onError := c.onError
+	if onError == nil {
+		onError = defaultOnError
+	}
+	onError(ctx, err)
+	return err
+}
+
+func defaultOnError(_ context.Context, err error) {
+	if err == nil {
+		os.Exit(0)
+	}
+
+	exitErr := asExitError(err)
+	if exitErr.Err != nil {
+		fmt.Fprintln(os.Stderr, exitErr.Err)
+	}
+	os.Exit(exitErr.Code)
+}
@@ -23,9 +23,9 @@ func main() {
 	}
 }
 
-func EchoHandler(ctx context.Context, flagSet *flag.FlagSet, args []string) error {
-	verbose := command.Lookup[bool](flagSet, "verbose")
-	textCase := command.Lookup[string](flagSet, "case")
+func EchoHandler(ctx context.Context, cmdCtx *command.Context) error {
+	verbose := command.Get[bool](cmdCtx, "verbose")
+	textCase := command.Get[string](cmdCtx, "case")
 
 	if verbose {
 		fmt.Println("command echo called with case: " + textCase)
@@ -33,13 +33,13 @@ func EchoHandler(ctx context.Context, flagSet *flag.FlagSet, args []string) erro
 
 	switch textCase {
 	case "upper":
-		fmt.Println(strings.ToUpper(strings.Join(args, " ")))
+		fmt.Println(strings.ToUpper(strings.Join(cmdCtx.Args, " ")))
 
 	case "lower":
-		fmt.Println(strings.ToLower(strings.Join(args, " ")))
+		fmt.Println(strings.ToLower(strings.Join(cmdCtx.Args, " ")))
 
 	default:
-		fmt.Println(strings.Join(args, " "))
+		fmt.Println(strings.Join(cmdCtx.Args, " "))
 	}
 
 	return nil
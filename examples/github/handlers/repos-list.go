@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"flag"
 	"fmt"
 
 	"github.com/google/go-github/v56/github"
@@ -10,8 +9,8 @@ import (
 )
 
 func ReposListHandler(ghClient *github.Client) command.Handler {
-	return func(ctx context.Context, flagSet *flag.FlagSet, _ []string) error {
-		user := command.Lookup[string](flagSet, "user")
+	return func(ctx context.Context, cmdCtx *command.Context) error {
+		user := command.Get[string](cmdCtx, "user")
 		if user == "" {
 			return fmt.Errorf("missing required flag: user")
 		}
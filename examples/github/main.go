@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"net/http"
 
 	"github.com/google/go-github/v56/github"
 	"github.com/pix-xip/go-command"
@@ -10,20 +12,56 @@ import (
 )
 
 func main() {
-	client := github.NewClient(nil)
+	httpClient := &http.Client{}
+	client := github.NewClient(httpClient)
 
 	root := command.Root().Flags(func(flagSet *flag.FlagSet) {
 		flagSet.Bool("verbose", false, "Enable verbose output")
 	}).Help("Example command")
 
+	root.Before(func(ctx context.Context, cmdCtx *command.Context) error {
+		if command.Get[bool](cmdCtx, "verbose") {
+			fmt.Println("github: connecting to", client.BaseURL)
+		}
+		return nil
+	})
+	root.After(func(ctx context.Context, cmdCtx *command.Context) error {
+		httpClient.CloseIdleConnections()
+		if err := command.HandlerError(ctx); command.Get[bool](cmdCtx, "verbose") && err == nil {
+			fmt.Println("github: closed idle connections")
+		}
+		return nil
+	})
+
 	reposCommand := root.SubCommand("repos").Help("Manage GitHub repositories")
 	{
 		reposCommand.SubCommand("list").Action(handlers.ReposListHandler(client)).Flags(func(flagSet *flag.FlagSet) {
 			flagSet.String("user", "", "GitHub user")
-		}).Help("List repositories of a GitHub user")
+		}).Help("List repositories of a GitHub user").CompleteFlag("user", completeUsers(client))
 	}
 
 	if err := root.Execute(context.Background()); err != nil {
 		panic(err)
 	}
 }
+
+// completeUsers returns a CompletionFunc that suggests GitHub logins matching
+// prefix, for the --user flag of repos list.
+func completeUsers(client *github.Client) command.CompletionFunc {
+	return func(ctx context.Context, prefix string) []string {
+		if prefix == "" {
+			return nil
+		}
+
+		result, _, err := client.Search.Users(ctx, prefix+" in:login", nil)
+		if err != nil {
+			return nil
+		}
+
+		candidates := make([]string, 0, len(result.Users))
+		for _, user := range result.Users {
+			candidates = append(candidates, user.GetLogin())
+		}
+		return candidates
+	}
+}
@@ -25,8 +25,8 @@ func main() {
 }
 
 func LevelMiddleware(next command.Handler) command.Handler {
-	return func(ctx context.Context, flagSet *flag.FlagSet, args []string) error {
-		switch level := command.Lookup[string](flagSet, "level"); level {
+	return func(ctx context.Context, cmdCtx *command.Context) error {
+		switch level := command.Get[string](cmdCtx, "level"); level {
 		case "debug":
 			slog.SetLogLoggerLevel(slog.LevelDebug)
 
@@ -43,16 +43,16 @@ func LevelMiddleware(next command.Handler) command.Handler {
 			return fmt.Errorf("unknown level: %s", level)
 		}
 
-		return next(ctx, flagSet, args)
+		return next(ctx, cmdCtx)
 	}
 }
 
-func InfoHandler(ctx context.Context, _ *flag.FlagSet, args []string) error {
-	slog.InfoContext(ctx, strings.Join(args, " "))
+func InfoHandler(ctx context.Context, cmdCtx *command.Context) error {
+	slog.InfoContext(ctx, strings.Join(cmdCtx.Args, " "))
 	return nil
 }
 
-func ErrorHandler(ctx context.Context, _ *flag.FlagSet, args []string) error {
-	slog.ErrorContext(ctx, strings.Join(args, " "))
+func ErrorHandler(ctx context.Context, cmdCtx *command.Context) error {
+	slog.ErrorContext(ctx, strings.Join(cmdCtx.Args, " "))
 	return nil
 }
@@ -0,0 +1,28 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Lookup retrieves the value of the flag named name from flagSet, asserting
+// it to type T. It panics if no such flag is declared or if its value does
+// not implement [flag.Getter] as T.
+func Lookup[T any](flagSet *flag.FlagSet, name string) T {
+	f := flagSet.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("command: no such flag: %s", name))
+	}
+
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		panic(fmt.Sprintf("command: flag %s does not implement flag.Getter", name))
+	}
+
+	value, ok := getter.Get().(T)
+	if !ok {
+		panic(fmt.Sprintf("command: flag %s is not of type %T", name, value))
+	}
+
+	return value
+}
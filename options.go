@@ -0,0 +1,232 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Context carries a resolved command's flags and positional arguments to its
+// [Handler]. Values declared through [Command.Options] should be read with
+// [Get]; raw *flag.FlagSet access remains available through FlagSet for
+// flags declared through [Command.Flags] or [flag.Lookup].
+type Context struct {
+	flagSet *flag.FlagSet
+	Args    []string
+}
+
+// FlagSet returns the underlying flag set, for interop with flags declared
+// through [Command.Flags] or direct [flag.Lookup] access.
+func (ctx *Context) FlagSet() *flag.FlagSet {
+	return ctx.flagSet
+}
+
+// Get retrieves the value of the option or flag named name, asserting it to
+// type T. It panics if no such flag is declared or if its value does not
+// implement [flag.Getter] as T.
+func Get[T any](ctx *Context, name string) T {
+	return Lookup[T](ctx.flagSet, name)
+}
+
+// OptionSpec registers itself onto a flag set when passed to
+// [Command.Options]. [Option] is the only implementation.
+type OptionSpec interface {
+	register(fs *flag.FlagSet) (name string, required bool)
+}
+
+// Option declares a single, typed command-line option. It is registered onto
+// a command's flag set through [Command.Options] and read back in a Handler
+// through [Get].
+type Option[T any] struct {
+	// Name is the flag name, e.g. "user" for a --user flag.
+	Name string
+
+	// Default is the value used when the option is not set by the config
+	// file, an environment variable, or the command line.
+	Default T
+
+	// Usage is shown next to the option in generated help output.
+	Usage string
+
+	// Required fails [Command.Execute] before middlewares run if the option
+	// is never set by the config file, an environment variable, or the
+	// command line, even if Default is non-zero.
+	Required bool
+
+	// EnvVar, if set, is read as the option's value before [Command.Config]
+	// or [Command.FromEnv] are applied, overriding Default.
+	EnvVar string
+
+	// Validate, if set, is called whenever the option is set, including by
+	// the config file, an environment variable, or the command line.
+	Validate func(T) error
+}
+
+func (o Option[T]) register(fs *flag.FlagSet) (string, bool) {
+	if o.Name == "" {
+		panic("command: option must have a name")
+	}
+
+	if fs.Lookup(o.Name) != nil {
+		panic(fmt.Sprintf("command: option already registered: %s", o.Name))
+	}
+
+	var zero T
+	switch any(zero).(type) {
+	case string, bool, int, int64, float64, time.Duration, []string:
+	default:
+		panic(fmt.Sprintf("command: option %s: unsupported option type %T", o.Name, zero))
+	}
+
+	value := newOptionValue(o.Default, o.Validate)
+	if o.EnvVar != "" {
+		if raw, ok := os.LookupEnv(o.EnvVar); ok {
+			if err := value.Set(raw); err != nil {
+				panic(fmt.Sprintf("command: option %s: invalid value in %s: %v", o.Name, o.EnvVar, err))
+			}
+		}
+	}
+
+	fs.Var(value, o.Name, o.Usage)
+
+	return o.Name, o.Required
+}
+
+// Options declares a command's typed options, alongside or instead of
+// [Command.Flags]. Registering two options with the same name panics, as
+// does registering an option without a name.
+func (c *command) Options(specs ...OptionSpec) Command {
+	for _, spec := range specs {
+		name, required := spec.register(c.flagSet)
+		if required {
+			c.requiredOptions = append(c.requiredOptions, name)
+		}
+	}
+
+	return c
+}
+
+// checkRequiredOptions fails before a command's middlewares and handler run
+// if any option in names was never set by the config file, an environment
+// variable, or the command line; a Default alone does not count as set.
+func checkRequiredOptions(fs *flag.FlagSet, names []string) error {
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+
+		setter, ok := f.Value.(interface{ wasSet() bool })
+		if !ok {
+			continue
+		}
+
+		if !setter.wasSet() {
+			return fmt.Errorf("command: missing required option: %s", name)
+		}
+	}
+
+	return nil
+}
+
+// optionValue is the generic [flag.Value] backing an [Option][T]. It parses
+// the common scalar types; anything else is a programming error caught at
+// registration via a panic from [Command.Options] rather than here.
+type optionValue[T any] struct {
+	value    *T
+	set      bool
+	validate func(T) error
+}
+
+func newOptionValue[T any](def T, validate func(T) error) *optionValue[T] {
+	value := def
+	return &optionValue[T]{value: &value, validate: validate}
+}
+
+func (v *optionValue[T]) String() string {
+	if v == nil || v.value == nil {
+		return ""
+	}
+	return fmt.Sprint(*v.value)
+}
+
+func (v *optionValue[T]) Get() any {
+	return *v.value
+}
+
+func (v *optionValue[T]) Set(raw string) error {
+	parsed, err := parseOptionValue[T](raw)
+	if err != nil {
+		return err
+	}
+
+	if v.validate != nil {
+		if err := v.validate(parsed); err != nil {
+			return err
+		}
+	}
+
+	*v.value = parsed
+	v.set = true
+	return nil
+}
+
+// wasSet reports whether Set has been called, distinguishing a value that
+// was explicitly supplied (including an explicit zero value) from one still
+// at Default. It backs [checkRequiredOptions].
+func (v *optionValue[T]) wasSet() bool {
+	return v.set
+}
+
+func parseOptionValue[T any](raw string) (T, error) {
+	var zero T
+
+	switch any(zero).(type) {
+	case string:
+		return any(raw).(T), nil
+
+	case bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return zero, err
+		}
+		return any(parsed).(T), nil
+
+	case int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return zero, err
+		}
+		return any(parsed).(T), nil
+
+	case int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return zero, err
+		}
+		return any(parsed).(T), nil
+
+	case float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return zero, err
+		}
+		return any(parsed).(T), nil
+
+	case time.Duration:
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return zero, err
+		}
+		return any(parsed).(T), nil
+
+	case []string:
+		return any(strings.Split(raw, ",")).(T), nil
+
+	default:
+		return zero, fmt.Errorf("command: unsupported option type %T", zero)
+	}
+}